@@ -0,0 +1,310 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Role represents a single role grant, either embedded in a createUser/updateUser
+// payload or returned by usersInfo/rolesInfo.
+type Role struct {
+	Db   string `mapstructure:"db" bson:"db"`
+	Role string `mapstructure:"role" bson:"role"`
+}
+
+// DbUser holds the fields of a mongodb_db_user resource that are sent to
+// createUser/updateUser.
+type DbUser struct {
+	Name     string
+	Password string
+	// DigestPassword mirrors createUser's digestPassword flag: when false, Password is
+	// already the MD5 digest mongoPasswordDigest computed, and the server must not
+	// re-hash it.
+	DigestPassword bool
+}
+
+// AuthenticationRestriction constrains the client and server addresses a user may
+// authenticate from/to, as accepted by createUser/updateUser.
+type AuthenticationRestriction struct {
+	ClientSource  []string `mapstructure:"client_source" bson:"clientSource,omitempty"`
+	ServerAddress []string `mapstructure:"server_address" bson:"serverAddress,omitempty"`
+}
+
+// UserInfo is the per-user document returned by the usersInfo command.
+type UserInfo struct {
+	Id                         string                      `bson:"_id"`
+	User                       string                      `bson:"user"`
+	Db                         string                      `bson:"db"`
+	Roles                      []Role                      `bson:"roles"`
+	Mechanisms                 []string                    `bson:"mechanisms"`
+	CustomData                 map[string]interface{}      `bson:"customData"`
+	AuthenticationRestrictions []AuthenticationRestriction `bson:"authenticationRestrictions"`
+}
+
+// UsersInfoResult is the decoded response of the usersInfo command.
+type UsersInfoResult struct {
+	Users []UserInfo `bson:"users"`
+}
+
+// getUser runs usersInfo for a single user in the given database.
+func getUser(client *mongo.Client, username string, database string) (UsersInfoResult, error) {
+	var result UsersInfoResult
+	adminDB := client.Database(database)
+	command := bson.D{{Key: "usersInfo", Value: bson.D{{Key: "user", Value: username}, {Key: "db", Value: database}}}}
+	err := adminDB.RunCommand(context.Background(), command).Decode(&result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// scramMechanismCredentials is the per-mechanism storedKey/serverKey/salt/iterationCount
+// document usersInfo returns under credentials.<mechanism> when showCredentials is set.
+type scramMechanismCredentials struct {
+	IterationCount int    `bson:"iterationCount"`
+	Salt           string `bson:"salt"`
+	StoredKey      string `bson:"storedKey"`
+	ServerKey      string `bson:"serverKey"`
+}
+
+// userCredentialsInfo is the per-user document returned by usersInfo when
+// showCredentials is requested, used to assert that credentials are untouched
+// by a roles-only update.
+type userCredentialsInfo struct {
+	User        string                               `bson:"user"`
+	Credentials map[string]scramMechanismCredentials `bson:"credentials"`
+}
+
+// userCredentialsInfoResult is the decoded response of the usersInfo command with
+// showCredentials: true.
+type userCredentialsInfoResult struct {
+	Users []userCredentialsInfo `bson:"users"`
+}
+
+// getUserCredentials runs usersInfo with showCredentials so callers can compare a
+// user's SCRAM storedKey/serverKey across operations that should not touch them.
+func getUserCredentials(client *mongo.Client, username string, database string) (userCredentialsInfoResult, error) {
+	var result userCredentialsInfoResult
+	adminDB := client.Database(database)
+	command := bson.D{
+		{Key: "usersInfo", Value: bson.D{{Key: "user", Value: username}, {Key: "db", Value: database}}},
+		{Key: "showCredentials", Value: true},
+	}
+	err := adminDB.RunCommand(context.Background(), command).Decode(&result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// createUser issues the createUser admin command for the given user/roles/mechanisms.
+// $external users (X.509, LDAP PLAIN, Kerberos GSSAPI) authenticate without a
+// locally stored password, and MongoDB rejects createUser outright if `pwd` is
+// present at all for them, so pwd/digestPassword are omitted rather than sent empty.
+func createUser(client *mongo.Client, user DbUser, roles []Role, mechanisms []interface{}, database string, restrictions []AuthenticationRestriction) error {
+	adminDB := client.Database(database)
+	command := bson.D{
+		{Key: "createUser", Value: user.Name},
+		{Key: "roles", Value: roles},
+	}
+	if !(database == "$external" && user.Password == "") {
+		command = append(command, bson.E{Key: "pwd", Value: user.Password})
+		if !user.DigestPassword {
+			command = append(command, bson.E{Key: "digestPassword", Value: false})
+		}
+	}
+	if len(mechanisms) > 0 {
+		command = append(command, bson.E{Key: "mechanisms", Value: mechanisms})
+	}
+	if len(restrictions) > 0 {
+		command = append(command, bson.E{Key: "authenticationRestrictions", Value: restrictions})
+	}
+	result := adminDB.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// updateUserFields carries the subset of updateUser parameters that changed
+// between the plan and the actual state, so only those are sent on the wire.
+type updateUserFields struct {
+	Password                   *string
+	PasswordIsDigest           bool
+	Mechanisms                 []interface{}
+	CustomData                 map[string]interface{}
+	AuthenticationRestrictions []AuthenticationRestriction
+	ClearRestrictions          bool
+}
+
+// updateUser issues the updateUser admin command with only the supplied fields set,
+// leaving everything else (including credentials) untouched.
+func updateUser(client *mongo.Client, username string, database string, fields updateUserFields) error {
+	adminDB := client.Database(database)
+	command := bson.D{{Key: "updateUser", Value: username}}
+	if fields.Password != nil {
+		command = append(command, bson.E{Key: "pwd", Value: *fields.Password})
+		if fields.PasswordIsDigest {
+			command = append(command, bson.E{Key: "digestPassword", Value: false})
+		}
+	}
+	if fields.Mechanisms != nil {
+		command = append(command, bson.E{Key: "mechanisms", Value: fields.Mechanisms})
+	}
+	if fields.CustomData != nil {
+		command = append(command, bson.E{Key: "customData", Value: fields.CustomData})
+	}
+	if fields.ClearRestrictions {
+		command = append(command, bson.E{Key: "authenticationRestrictions", Value: []AuthenticationRestriction{}})
+	} else if fields.AuthenticationRestrictions != nil {
+		command = append(command, bson.E{Key: "authenticationRestrictions", Value: fields.AuthenticationRestrictions})
+	}
+	if len(command) == 1 {
+		// Nothing changed besides roles, which go through grantRolesToUser/revokeRolesFromUser.
+		return nil
+	}
+	result := adminDB.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// grantRolesToUser adds roles to an existing user without touching credentials.
+func grantRolesToUser(client *mongo.Client, username string, database string, roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	adminDB := client.Database(database)
+	command := bson.D{{Key: "grantRolesToUser", Value: username}, {Key: "roles", Value: roles}}
+	result := adminDB.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// revokeRolesFromUser removes roles from an existing user without touching credentials.
+func revokeRolesFromUser(client *mongo.Client, username string, database string, roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	adminDB := client.Database(database)
+	command := bson.D{{Key: "revokeRolesFromUser", Value: username}, {Key: "roles", Value: roles}}
+	result := adminDB.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// diffRoles splits the desired role set against the actual role set into
+// roles to grant and roles to revoke.
+func diffRoles(actual []Role, desired []Role) (toGrant []Role, toRevoke []Role) {
+	actualSet := make(map[Role]bool, len(actual))
+	for _, r := range actual {
+		actualSet[r] = true
+	}
+	desiredSet := make(map[Role]bool, len(desired))
+	for _, r := range desired {
+		desiredSet[r] = true
+	}
+	for _, r := range desired {
+		if !actualSet[r] {
+			toGrant = append(toGrant, r)
+		}
+	}
+	for _, r := range actual {
+		if !desiredSet[r] {
+			toRevoke = append(toRevoke, r)
+		}
+	}
+	return toGrant, toRevoke
+}
+
+// PrivilegeResource identifies what a privilege applies to: either a db/collection
+// pair, or the whole cluster.
+type PrivilegeResource struct {
+	Db          string `mapstructure:"db" bson:"db,omitempty"`
+	Collection  string `mapstructure:"collection" bson:"collection,omitempty"`
+	Cluster     bool   `mapstructure:"cluster" bson:"cluster,omitempty"`
+	AnyResource bool   `mapstructure:"any_resource" bson:"anyResource,omitempty"`
+}
+
+// Privilege is a single resource+actions pair, as accepted by createRole/updateRole.
+type Privilege struct {
+	Resource PrivilegeResource `mapstructure:"resource" bson:"resource"`
+	Actions  []string          `mapstructure:"actions" bson:"actions"`
+}
+
+// CustomRole holds the fields of a mongodb_custom_role resource that are sent to
+// createRole/updateRole.
+type CustomRole struct {
+	Name                       string
+	Privileges                 []Privilege
+	InheritedRoles             []Role
+	AuthenticationRestrictions []AuthenticationRestriction
+}
+
+// RoleInfo is the per-role document returned by the rolesInfo command when
+// showPrivileges is set.
+type RoleInfo struct {
+	Role                       string                      `bson:"role"`
+	Db                         string                      `bson:"db"`
+	Privileges                 []Privilege                 `bson:"privileges"`
+	InheritedRoles             []Role                      `bson:"inheritedRoles"`
+	AuthenticationRestrictions []AuthenticationRestriction `bson:"authenticationRestrictions"`
+}
+
+// RolesInfoResult is the decoded response of the rolesInfo command.
+type RolesInfoResult struct {
+	Roles []RoleInfo `bson:"roles"`
+}
+
+// createRole issues the createRole admin command.
+func createRole(client *mongo.Client, role CustomRole, database string) error {
+	db := client.Database(database)
+	command := bson.D{
+		{Key: "createRole", Value: role.Name},
+		{Key: "privileges", Value: role.Privileges},
+		{Key: "roles", Value: role.InheritedRoles},
+	}
+	if len(role.AuthenticationRestrictions) > 0 {
+		command = append(command, bson.E{Key: "authenticationRestrictions", Value: role.AuthenticationRestrictions})
+	}
+	result := db.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// updateRole issues the updateRole admin command, replacing privileges, inherited
+// roles, and authenticationRestrictions wholesale (as the command itself requires).
+// Unlike createRole, updateRole leaves any field it isn't given untouched rather than
+// clearing it, so authenticationRestrictions must always be sent -- including as an
+// empty slice -- or restrictions removed from config would never be cleared server-side.
+func updateRole(client *mongo.Client, role CustomRole, database string) error {
+	db := client.Database(database)
+	restrictions := role.AuthenticationRestrictions
+	if restrictions == nil {
+		restrictions = []AuthenticationRestriction{}
+	}
+	command := bson.D{
+		{Key: "updateRole", Value: role.Name},
+		{Key: "privileges", Value: role.Privileges},
+		{Key: "roles", Value: role.InheritedRoles},
+		{Key: "authenticationRestrictions", Value: restrictions},
+	}
+	result := db.RunCommand(context.Background(), command)
+	return result.Err()
+}
+
+// dropRole issues the dropRole admin command.
+func dropRole(client *mongo.Client, roleName string, database string) error {
+	db := client.Database(database)
+	result := db.RunCommand(context.Background(), bson.D{{Key: "dropRole", Value: roleName}})
+	return result.Err()
+}
+
+// getRole runs rolesInfo with showPrivileges for a single role in the given database.
+func getRole(client *mongo.Client, roleName string, database string) (RolesInfoResult, error) {
+	var result RolesInfoResult
+	db := client.Database(database)
+	command := bson.D{
+		{Key: "rolesInfo", Value: bson.D{{Key: "role", Value: roleName}, {Key: "db", Value: database}}},
+		{Key: "showPrivileges", Value: true},
+	}
+	err := db.RunCommand(context.Background(), command).Decode(&result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
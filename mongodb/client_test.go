@@ -0,0 +1,70 @@
+package mongodb
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sortRoles(roles []Role) []Role {
+	sorted := append([]Role(nil), roles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Db != sorted[j].Db {
+			return sorted[i].Db < sorted[j].Db
+		}
+		return sorted[i].Role < sorted[j].Role
+	})
+	return sorted
+}
+
+func TestDiffRoles(t *testing.T) {
+	cases := []struct {
+		name       string
+		actual     []Role
+		desired    []Role
+		wantGrant  []Role
+		wantRevoke []Role
+	}{
+		{
+			name:    "no change",
+			actual:  []Role{{Db: "admin", Role: "readWrite"}},
+			desired: []Role{{Db: "admin", Role: "readWrite"}},
+		},
+		{
+			name:      "add a role",
+			actual:    []Role{{Db: "admin", Role: "read"}},
+			desired:   []Role{{Db: "admin", Role: "read"}, {Db: "admin", Role: "readWrite"}},
+			wantGrant: []Role{{Db: "admin", Role: "readWrite"}},
+		},
+		{
+			name:       "remove a role",
+			actual:     []Role{{Db: "admin", Role: "read"}, {Db: "admin", Role: "readWrite"}},
+			desired:    []Role{{Db: "admin", Role: "read"}},
+			wantRevoke: []Role{{Db: "admin", Role: "readWrite"}},
+		},
+		{
+			name:       "swap a role",
+			actual:     []Role{{Db: "admin", Role: "read"}},
+			desired:    []Role{{Db: "admin", Role: "readWrite"}},
+			wantGrant:  []Role{{Db: "admin", Role: "readWrite"}},
+			wantRevoke: []Role{{Db: "admin", Role: "read"}},
+		},
+		{
+			name:    "empty both",
+			actual:  nil,
+			desired: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			toGrant, toRevoke := diffRoles(tc.actual, tc.desired)
+			if !reflect.DeepEqual(sortRoles(toGrant), sortRoles(tc.wantGrant)) {
+				t.Errorf("toGrant = %v, want %v", toGrant, tc.wantGrant)
+			}
+			if !reflect.DeepEqual(sortRoles(toRevoke), sortRoles(tc.wantRevoke)) {
+				t.Errorf("toRevoke = %v, want %v", toRevoke, tc.wantRevoke)
+			}
+		})
+	}
+}
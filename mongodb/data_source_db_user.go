@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDatabaseUser() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDatabaseUserRead,
+		Schema: map[string]*schema.Schema{
+			"auth_database": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"role": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"db": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"role": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"auth_mechanisms": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"custom_data": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"authentication_restriction": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"client_source": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"server_address": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDatabaseUserRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	meta := i.(*MongoProviderMeta)
+	client := meta.Client
+	database := data.Get("auth_database").(string)
+	userName := data.Get("name").(string)
+
+	result, err := getUser(client, userName, database)
+	if err != nil {
+		return diag.Errorf("Error reading user : %s ", err)
+	}
+	if len(result.Users) == 0 {
+		return diag.Errorf("user does not exist")
+	}
+	userInfo := result.Users[0]
+
+	roles := make([]interface{}, len(userInfo.Roles))
+	for i, r := range userInfo.Roles {
+		roles[i] = map[string]interface{}{
+			"db":   r.Db,
+			"role": r.Role,
+		}
+	}
+	restrictions := make([]interface{}, len(userInfo.AuthenticationRestrictions))
+	for i, r := range userInfo.AuthenticationRestrictions {
+		restrictions[i] = map[string]interface{}{
+			"client_source":  r.ClientSource,
+			"server_address": r.ServerAddress,
+		}
+	}
+
+	if err := data.Set("role", roles); err != nil {
+		return diag.Errorf("error setting role : %s ", err)
+	}
+	if err := data.Set("auth_mechanisms", userInfo.Mechanisms); err != nil {
+		return diag.Errorf("error setting auth_mechanisms : %s ", err)
+	}
+	if err := data.Set("custom_data", userInfo.CustomData); err != nil {
+		return diag.Errorf("error setting custom_data : %s ", err)
+	}
+	if err := data.Set("authentication_restriction", restrictions); err != nil {
+		return diag.Errorf("error setting authentication_restriction : %s ", err)
+	}
+
+	data.SetId(userInfo.Id)
+	return nil
+}
@@ -0,0 +1,175 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoProviderMeta is passed as the meta argument to every resource's
+// CRUD functions and holds the connected mongo.Client.
+type MongoProviderMeta struct {
+	Client *mongo.Client
+}
+
+func Provider() *schema.Provider {
+	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:        schema.TypeString,
+				Required:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MONGODB_HOST", nil),
+			},
+			"port": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  27017,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"master_db": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "admin",
+			},
+			"tls": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"tls_ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tls_certificate_key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tls_certificate_key_file_password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"tls_insecure": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			"mongodb_db_user":     resourceDatabaseUser(),
+			"mongodb_custom_role": resourceCustomRole(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"mongodb_db_user": dataSourceDatabaseUser(),
+		},
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	host := data.Get("host").(string)
+	port := data.Get("port").(int)
+	username := data.Get("username").(string)
+	password := data.Get("password").(string)
+
+	uri := fmt.Sprintf("mongodb://%s:%d", host, port)
+	clientOptions := options.Client().ApplyURI(uri)
+	if username != "" {
+		clientOptions.SetAuth(options.Credential{Username: username, Password: password})
+	}
+
+	if data.Get("tls").(bool) {
+		tlsConfig, err := buildTLSConfig(data)
+		if err != nil {
+			return nil, diag.Errorf("could not build TLS config : %s ", err)
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, diag.Errorf("could not connect to mongodb : %s ", err)
+	}
+
+	return &MongoProviderMeta{Client: client}, nil
+}
+
+// buildTLSConfig assembles a *tls.Config from the provider's tls_* attributes,
+// so operators can connect over mTLS and manage $external X.509 users.
+func buildTLSConfig(data *schema.ResourceData) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: data.Get("tls_insecure").(bool),
+	}
+
+	if caFile := data.Get("tls_ca_file").(string); caFile != "" {
+		caBytes, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read tls_ca_file : %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("could not parse any certificates from tls_ca_file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if keyFile := data.Get("tls_certificate_key_file").(string); keyFile != "" {
+		certBytes, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read tls_certificate_key_file : %s", err)
+		}
+		if keyPassword := data.Get("tls_certificate_key_file_password").(string); keyPassword != "" {
+			certBytes, err = decryptPEMBlocks(certBytes, keyPassword)
+			if err != nil {
+				return nil, fmt.Errorf("could not decrypt tls_certificate_key_file : %s", err)
+			}
+		}
+		cert, err := tls.X509KeyPair(certBytes, certBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse tls_certificate_key_file : %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// decryptPEMBlocks walks every PEM block in pemBytes and decrypts the ones
+// protected with a password (legacy RFC 1423 encryption), re-encoding the
+// result as a plain PEM bundle that crypto/tls can parse.
+func decryptPEMBlocks(pemBytes []byte, password string) ([]byte, error) {
+	var out []byte
+	rest := pemBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if x509.IsEncryptedPEMBlock(block) {
+			der, err := x509.DecryptPEMBlock(block, []byte(password))
+			if err != nil {
+				return nil, err
+			}
+			block = &pem.Block{Type: block.Type, Bytes: der}
+		}
+		out = append(out, pem.EncodeToMemory(block)...)
+	}
+	return out, nil
+}
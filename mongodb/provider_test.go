@@ -0,0 +1,37 @@
+package mongodb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var testAccProviders map[string]*schema.Provider
+var testAccProvider *schema.Provider
+
+func init() {
+	testAccProvider = Provider()
+	testAccProviders = map[string]*schema.Provider{
+		"mongodb": testAccProvider,
+	}
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestProvider_impl(t *testing.T) {
+	var _ = Provider()
+}
+
+// testAccPreCheck is run before every acceptance test step; resource.Test already
+// skips the whole suite unless TF_ACC is set, this only fails fast with a clearer
+// message when TF_ACC is set but the target deployment wasn't configured.
+func testAccPreCheck(t *testing.T) {
+	if os.Getenv("MONGODB_HOST") == "" {
+		t.Fatal("MONGODB_HOST must be set for acceptance tests")
+	}
+}
@@ -0,0 +1,281 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mitchellh/mapstructure"
+)
+
+func resourceCustomRole() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCustomRoleCreate,
+		ReadContext:   resourceCustomRoleRead,
+		UpdateContext: resourceCustomRoleUpdate,
+		DeleteContext: resourceCustomRoleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"privilege": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource": {
+							Type:     schema.TypeList,
+							Required: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"db": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"collection": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"cluster": {
+										Type:     schema.TypeBool,
+										Optional: true,
+									},
+									"any_resource": {
+										Type:     schema.TypeBool,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"actions": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"inherited_role": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"db": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"role": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+			"authentication_restriction": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"client_source": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"server_address": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildCustomRole decodes the privilege/inherited_role blocks out of resource data.
+func buildCustomRole(data *schema.ResourceData) (CustomRole, error) {
+	role := CustomRole{Name: data.Get("name").(string)}
+
+	privileges := data.Get("privilege").(*schema.Set).List()
+	for _, raw := range privileges {
+		p := raw.(map[string]interface{})
+		var privilege Privilege
+		resources := p["resource"].([]interface{})
+		if len(resources) != 1 {
+			return role, fmt.Errorf("each privilege must have exactly one resource block")
+		}
+		if err := mapstructure.Decode(resources[0], &privilege.Resource); err != nil {
+			return role, err
+		}
+		for _, action := range p["actions"].([]interface{}) {
+			privilege.Actions = append(privilege.Actions, action.(string))
+		}
+		role.Privileges = append(role.Privileges, privilege)
+	}
+
+	inheritedRoles := data.Get("inherited_role").(*schema.Set).List()
+	if err := mapstructure.Decode(inheritedRoles, &role.InheritedRoles); err != nil {
+		return role, err
+	}
+
+	restrictions, err := buildAuthenticationRestrictions(data)
+	if err != nil {
+		return role, err
+	}
+	role.AuthenticationRestrictions = restrictions
+
+	return role, nil
+}
+
+func resourceCustomRoleCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	meta := i.(*MongoProviderMeta)
+	client := meta.Client
+	database := data.Get("database").(string)
+
+	role, err := buildCustomRole(data)
+	if err != nil {
+		return diag.Errorf("Error decoding role : %s ", err)
+	}
+
+	if err := createRole(client, role, database); err != nil {
+		return diag.Errorf("Could not create the role : %s ", err)
+	}
+
+	str := database + "." + role.Name
+	encoded := base64.StdEncoding.EncodeToString([]byte(str))
+	data.SetId(encoded)
+	return resourceCustomRoleRead(ctx, data, i)
+}
+
+func resourceCustomRoleRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	meta := i.(*MongoProviderMeta)
+	client := meta.Client
+	stateID := data.State().ID
+	roleName, database, err := resourceCustomRoleParseId(stateID)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	result, err := getRole(client, roleName, database)
+	if err != nil {
+		return diag.Errorf("Error reading role : %s ", err)
+	}
+	if len(result.Roles) == 0 {
+		return diag.Errorf("role does not exist")
+	}
+	roleInfo := result.Roles[0]
+
+	privileges := make([]interface{}, len(roleInfo.Privileges))
+	for i, p := range roleInfo.Privileges {
+		privileges[i] = map[string]interface{}{
+			"resource": []interface{}{
+				map[string]interface{}{
+					"db":           p.Resource.Db,
+					"collection":   p.Resource.Collection,
+					"cluster":      p.Resource.Cluster,
+					"any_resource": p.Resource.AnyResource,
+				},
+			},
+			"actions": p.Actions,
+		}
+	}
+
+	inheritedRoles := make([]interface{}, len(roleInfo.InheritedRoles))
+	for i, r := range roleInfo.InheritedRoles {
+		inheritedRoles[i] = map[string]interface{}{
+			"db":   r.Db,
+			"role": r.Role,
+		}
+	}
+
+	restrictions := make([]interface{}, len(roleInfo.AuthenticationRestrictions))
+	for i, r := range roleInfo.AuthenticationRestrictions {
+		restrictions[i] = map[string]interface{}{
+			"client_source":  r.ClientSource,
+			"server_address": r.ServerAddress,
+		}
+	}
+
+	if err := data.Set("name", roleInfo.Role); err != nil {
+		return diag.Errorf("error setting name : %s ", err)
+	}
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("privilege", privileges); err != nil {
+		return diag.Errorf("error setting privilege : %s ", err)
+	}
+	if err := data.Set("inherited_role", inheritedRoles); err != nil {
+		return diag.Errorf("error setting inherited_role : %s ", err)
+	}
+	if err := data.Set("authentication_restriction", restrictions); err != nil {
+		return diag.Errorf("error setting authentication_restriction : %s ", err)
+	}
+	data.SetId(stateID)
+	return nil
+}
+
+func resourceCustomRoleUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	meta := i.(*MongoProviderMeta)
+	client := meta.Client
+	database := data.Get("database").(string)
+
+	role, err := buildCustomRole(data)
+	if err != nil {
+		return diag.Errorf("Error decoding role : %s ", err)
+	}
+
+	if err := updateRole(client, role, database); err != nil {
+		return diag.Errorf("Could not update the role : %s ", err)
+	}
+
+	return resourceCustomRoleRead(ctx, data, i)
+}
+
+func resourceCustomRoleDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	meta := i.(*MongoProviderMeta)
+	client := meta.Client
+	database := data.Get("database").(string)
+	roleName := data.Get("name").(string)
+
+	if err := dropRole(client, roleName, database); err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	return nil
+}
+
+func resourceCustomRoleParseId(id string) (string, string, error) {
+	result, errEncoding := base64.StdEncoding.DecodeString(id)
+	if errEncoding != nil {
+		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(result), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected database.role", id)
+	}
+
+	database := parts[0]
+	roleName := parts[1]
+
+	return roleName, database, nil
+}
@@ -2,16 +2,81 @@ package mongodb
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/mitchellh/mapstructure"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+const (
+	scramSHA1Iterations   = 10000
+	scramSHA256Iterations = 15000
+	scramSaltLen          = 16
+)
+
+// credentialDigest derives a SCRAM-SHA-1 storedKey:serverKey digest for the given
+// password, recorded in state as credential_digest whenever a password is set. The
+// salt is random on every call, so two digests of the same password differ and the
+// value is not compared against anything; password drift in "client" mode is
+// tracked through passwordStateDigest instead.
+func credentialDigest(password string) (string, error) {
+	salt := make([]byte, scramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	creds, err := computeScramCredentials("SCRAM-SHA-1", password, salt, scramSHA1Iterations)
+	if err != nil {
+		return "", err
+	}
+	_, storedKey, serverKey := creds.encode()
+	return storedKey + ":" + serverKey, nil
+}
+
+// passwordStateDigest is a deterministic (unsalted) hash of password used only so
+// Terraform can tell a config password apart from the one already applied without
+// persisting it in state; it is not a credential and is never sent to Mongo.
+func passwordStateDigest(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// suppressClientDigestedPassword hides the diff between a plaintext config password and
+// the passwordStateDigest already recorded in state for password_digestor = "client", so
+// that mode can keep its promise of not persisting the plaintext password in state.
+func suppressClientDigestedPassword(k string, old string, new string, data *schema.ResourceData) bool {
+	if data.Get("password_digestor").(string) != "client" || new == "" {
+		return false
+	}
+	return old == passwordStateDigest(new)
+}
+
+const mechanismSCRAMSHA1 = "SCRAM-SHA-1"
+
+// clientDigestMechanisms returns the auth_mechanisms to use when password_digestor is
+// "client": mongoPasswordDigest is only a valid createUser/updateUser `pwd` value for
+// SCRAM-SHA-1 (legacy MONGODB-CR digest). SCRAM-SHA-256 needs the raw password to derive
+// correct credentials, so it must be rejected rather than silently creating a user whose
+// real credentials don't match what the operator typed.
+func clientDigestMechanisms(authMechanisms []interface{}) ([]interface{}, error) {
+	if len(authMechanisms) == 0 {
+		return []interface{}{mechanismSCRAMSHA1}, nil
+	}
+	for _, m := range authMechanisms {
+		if m.(string) != mechanismSCRAMSHA1 {
+			return nil, fmt.Errorf("password_digestor = \"client\" only supports auth_mechanisms = [\"SCRAM-SHA-1\"], got %q", m)
+		}
+	}
+	return authMechanisms, nil
+}
+
 func resourceDatabaseUser() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceDatabaseUserCreate,
@@ -19,7 +84,7 @@ func resourceDatabaseUser() *schema.Resource {
 		UpdateContext: resourceDatabaseUserUpdate,
 		DeleteContext: resourceDatabaseUserDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceDatabaseUserImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"auth_database": {
@@ -31,8 +96,11 @@ func resourceDatabaseUser() *schema.Resource {
 				Required: true,
 			},
 			"password": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressClientDigestedPassword,
 			},
 			"auth_mechanisms": {
 				Type:     schema.TypeSet,
@@ -58,10 +126,108 @@ func resourceDatabaseUser() *schema.Resource {
 					},
 				},
 			},
+			"custom_data": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"x509_subject": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "RFC2253 subject DN (e.g. `CN=alice,OU=Ops,O=Acme`) of the client certificate to authenticate, for `auth_database = \"$external\"` users.",
+			},
+			"authentication_restriction": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"client_source": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"server_address": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"password_digestor": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "server",
+				ValidateFunc: validation.StringInSlice([]string{"server", "client"}, false),
+				Description:  "When \"client\", SCRAM credentials are derived locally and only the MD5 pre-digest is sent to createUser/updateUser, so the raw password never travels as-is, and \"password\" is stored in state as a non-reversible digest instead of the plaintext.",
+			},
+			"credential_digest": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
 		},
 	}
 }
 
+const (
+	mechanismX509   = "MONGODB-X509"
+	mechanismPlain  = "PLAIN"
+	mechanismGSSAPI = "GSSAPI"
+)
+
+// externalMechanisms are the mechanisms createUser accepts for $external users
+// that authenticate without a locally stored password.
+var externalMechanisms = map[string]bool{
+	mechanismX509:   true,
+	mechanismPlain:  true,
+	mechanismGSSAPI: true,
+}
+
+// isX509User reports whether the resource is configured as a certificate-authenticated
+// $external user rather than a password-authenticated one.
+func isX509User(data *schema.ResourceData) bool {
+	return data.Get("auth_database").(string) == "$external" && data.Get("x509_subject").(string) != ""
+}
+
+// isExternalUser reports whether the resource is any kind of $external user
+// (X.509, LDAP PLAIN passthrough, or Kerberos GSSAPI) that has no locally
+// stored password.
+func isExternalUser(data *schema.ResourceData) bool {
+	return data.Get("auth_database").(string) == "$external" && data.Get("password").(string) == ""
+}
+
+// validateExternalRoles rejects role dbs that a $external user cannot be granted
+// roles from ("" or "$external" itself, which holds no grantable roles).
+func validateExternalRoles(database string, roles []Role) error {
+	if database != "$external" {
+		return nil
+	}
+	for _, r := range roles {
+		if r.Db == "" || r.Db == "$external" {
+			return fmt.Errorf("role %q for an $external user must specify a grantable db, not %q", r.Role, r.Db)
+		}
+	}
+	return nil
+}
+
+// buildAuthenticationRestrictions decodes the authentication_restriction blocks out
+// of resource data.
+func buildAuthenticationRestrictions(data *schema.ResourceData) ([]AuthenticationRestriction, error) {
+	var restrictions []AuthenticationRestriction
+	raw := data.Get("authentication_restriction").([]interface{})
+	if err := mapstructure.Decode(raw, &restrictions); err != nil {
+		return nil, err
+	}
+	return restrictions, nil
+}
+
 func resourceDatabaseUserDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
 	meta := i.(*MongoProviderMeta)
 	client := meta.Client
@@ -90,41 +256,93 @@ func resourceDatabaseUserDelete(ctx context.Context, data *schema.ResourceData,
 func resourceDatabaseUserUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
 	meta := i.(*MongoProviderMeta)
 	client := meta.Client
-	var stateId = data.State().ID
-	_, errEncoding := base64.StdEncoding.DecodeString(stateId)
-	if errEncoding != nil {
-		return diag.Errorf("ID mismatch %s", errEncoding)
-	}
+	stateId := data.State().ID
 
-	var userName = data.Get("name").(string)
-	var database = data.Get("auth_database").(string)
-	var userPassword = data.Get("password").(string)
-	var authMechanisms = data.Get("auth_mechanisms").(*schema.Set).List()
-
-	adminDB := client.Database(database)
+	// The Mongo user's real identity is whatever was stored in the ID at Create
+	// (x509_subject for X.509 users, name otherwise) -- not data.Get("name"), which for
+	// an X.509 user is just a decorative label unrelated to the user Mongo knows about.
+	userName, database, err := resourceDatabaseUserParseId(stateId)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
 
-	result := adminDB.RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: userName}})
-	if result.Err() != nil {
-		return diag.Errorf("%s", result.Err())
+	actual, getErr := getUser(client, userName, database)
+	if getErr != nil {
+		return diag.Errorf("Could not read the user : %s ", getErr)
 	}
-	var roleList []Role
-	var user = DbUser{
-		Name:     userName,
-		Password: userPassword,
+	if len(actual.Users) == 0 {
+		return diag.Errorf("user does not exist")
 	}
+
+	var desiredRoles []Role
 	roles := data.Get("role").(*schema.Set).List()
-	roleMapErr := mapstructure.Decode(roles, &roleList)
+	roleMapErr := mapstructure.Decode(roles, &desiredRoles)
 	if roleMapErr != nil {
 		return diag.Errorf("Error decoding map : %s ", roleMapErr)
 	}
-	err2 := createUser(client, user, roleList, authMechanisms, database)
-	if err2 != nil {
-		return diag.Errorf("Could not create the user : %s ", err2)
+	if err := validateExternalRoles(database, desiredRoles); err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	toGrant, toRevoke := diffRoles(actual.Users[0].Roles, desiredRoles)
+	if len(toGrant) > 0 {
+		if err := grantRolesToUser(client, userName, database, toGrant); err != nil {
+			return diag.Errorf("Could not grant roles to the user : %s ", err)
+		}
+	}
+	if len(toRevoke) > 0 {
+		if err := revokeRolesFromUser(client, userName, database, toRevoke); err != nil {
+			return diag.Errorf("Could not revoke roles from the user : %s ", err)
+		}
+	}
+
+	var fields updateUserFields
+	if data.HasChange("password") {
+		password := data.Get("password").(string)
+		if password != "" && data.Get("password_digestor").(string) == "client" {
+			forced, mechErr := clientDigestMechanisms(data.Get("auth_mechanisms").(*schema.Set).List())
+			if mechErr != nil {
+				return diag.Errorf("%s", mechErr)
+			}
+			digest := mongoPasswordDigest(userName, password)
+			fields.Password = &digest
+			fields.PasswordIsDigest = true
+			fields.Mechanisms = forced
+			if err := data.Set("password", passwordStateDigest(password)); err != nil {
+				return diag.Errorf("error setting password : %s ", err)
+			}
+		} else {
+			fields.Password = &password
+		}
+		digest, digestErr := credentialDigest(password)
+		if digestErr != nil {
+			return diag.Errorf("Could not compute credential digest : %s ", digestErr)
+		}
+		if err := data.Set("credential_digest", digest); err != nil {
+			return diag.Errorf("error setting credential_digest : %s ", err)
+		}
+	}
+	if fields.Mechanisms == nil && data.HasChange("auth_mechanisms") {
+		fields.Mechanisms = data.Get("auth_mechanisms").(*schema.Set).List()
+	}
+	if data.HasChange("custom_data") {
+		fields.CustomData = data.Get("custom_data").(map[string]interface{})
+	}
+	if data.HasChange("authentication_restriction") {
+		restrictions, restrictionsErr := buildAuthenticationRestrictions(data)
+		if restrictionsErr != nil {
+			return diag.Errorf("Error decoding authentication_restriction : %s ", restrictionsErr)
+		}
+		if len(restrictions) == 0 {
+			fields.ClearRestrictions = true
+		} else {
+			fields.AuthenticationRestrictions = restrictions
+		}
+	}
+	if err := updateUser(client, userName, database, fields); err != nil {
+		return diag.Errorf("Could not update the user : %s ", err)
 	}
 
-	newId := database + "." + userName
-	encoded := base64.StdEncoding.EncodeToString([]byte(newId))
-	data.SetId(encoded)
 	return resourceDatabaseUserRead(ctx, data, i)
 }
 
@@ -159,9 +377,35 @@ func resourceDatabaseUserRead(ctx context.Context, data *schema.ResourceData, i
 	if dataSetError != nil {
 		return diag.Errorf("error setting auth_db : %s ", dataSetError)
 	}
-	dataSetError = data.Set("password", data.Get("password"))
+	// In "client" mode, Create/Update already replaced "password" with
+	// passwordStateDigest so the plaintext is never persisted; re-setting it here to
+	// data.Get("password") would just write the plaintext config value right back.
+	if !isExternalUser(data) && data.Get("password_digestor").(string) != "client" {
+		dataSetError = data.Set("password", data.Get("password"))
+		if dataSetError != nil {
+			return diag.Errorf("error setting password : %s ", dataSetError)
+		}
+	}
+	if database == "$external" {
+		dataSetError = data.Set("auth_mechanisms", result.Users[0].Mechanisms)
+		if dataSetError != nil {
+			return diag.Errorf("error setting auth_mechanisms : %s ", dataSetError)
+		}
+	}
+	dataSetError = data.Set("custom_data", result.Users[0].CustomData)
+	if dataSetError != nil {
+		return diag.Errorf("error setting custom_data : %s ", dataSetError)
+	}
+	restrictions := make([]interface{}, len(result.Users[0].AuthenticationRestrictions))
+	for i, r := range result.Users[0].AuthenticationRestrictions {
+		restrictions[i] = map[string]interface{}{
+			"client_source":  r.ClientSource,
+			"server_address": r.ServerAddress,
+		}
+	}
+	dataSetError = data.Set("authentication_restriction", restrictions)
 	if dataSetError != nil {
-		return diag.Errorf("error setting password : %s ", dataSetError)
+		return diag.Errorf("error setting authentication_restriction : %s ", dataSetError)
 	}
 	data.SetId(stateID)
 	return nil
@@ -173,34 +417,121 @@ func resourceDatabaseUserCreate(ctx context.Context, data *schema.ResourceData,
 	var database = data.Get("auth_database").(string)
 	var userName = data.Get("name").(string)
 	var userPassword = data.Get("password").(string)
+	var authMechanisms = data.Get("auth_mechanisms").(*schema.Set).List()
+
+	if isX509User(data) {
+		if userPassword != "" {
+			return diag.Errorf("password must not be set when x509_subject is used")
+		}
+		userName = data.Get("x509_subject").(string)
+		authMechanisms = []interface{}{mechanismX509}
+	} else if isExternalUser(data) {
+		// LDAP passthrough (PLAIN) or Kerberos (GSSAPI): userName is the raw LDAP DN/username
+		// or the "user@REALM" principal, and the deployment's own authenticationMechanisms
+		// setParameter decides which of these actually authenticate.
+		for _, m := range authMechanisms {
+			if !externalMechanisms[m.(string)] {
+				return diag.Errorf("auth_mechanisms for a $external user without a password must be one of PLAIN, GSSAPI, or MONGODB-X509, got %q", m)
+			}
+		}
+		if len(authMechanisms) == 0 {
+			authMechanisms = []interface{}{mechanismPlain}
+		}
+	}
+
+	if userPassword != "" && data.Get("password_digestor").(string) == "client" {
+		forced, mechErr := clientDigestMechanisms(authMechanisms)
+		if mechErr != nil {
+			return diag.Errorf("%s", mechErr)
+		}
+		authMechanisms = forced
+	}
+
 	var roleList []Role
 	var user = DbUser{
-		Name:     userName,
-		Password: userPassword,
+		Name:           userName,
+		Password:       userPassword,
+		DigestPassword: true,
+	}
+	if userPassword != "" && data.Get("password_digestor").(string) == "client" {
+		user.Password = mongoPasswordDigest(userName, userPassword)
+		user.DigestPassword = false
 	}
-	var authMechanisms = data.Get("auth_mechanisms").(*schema.Set).List()
 	roles := data.Get("role").(*schema.Set).List()
 	roleMapErr := mapstructure.Decode(roles, &roleList)
 	if roleMapErr != nil {
 		return diag.Errorf("Error decoding map : %s ", roleMapErr)
 	}
-	err := createUser(client, user, roleList, authMechanisms, database)
+	if err := validateExternalRoles(database, roleList); err != nil {
+		return diag.Errorf("%s", err)
+	}
+	restrictions, restrictionsErr := buildAuthenticationRestrictions(data)
+	if restrictionsErr != nil {
+		return diag.Errorf("Error decoding authentication_restriction : %s ", restrictionsErr)
+	}
+	err := createUser(client, user, roleList, authMechanisms, database, restrictions)
 	if err != nil {
 		return diag.Errorf("Could not create the user : %s ", err)
 	}
+	if userPassword != "" {
+		digest, digestErr := credentialDigest(userPassword)
+		if digestErr != nil {
+			return diag.Errorf("Could not compute credential digest : %s ", digestErr)
+		}
+		if err := data.Set("credential_digest", digest); err != nil {
+			return diag.Errorf("error setting credential_digest : %s ", err)
+		}
+		if data.Get("password_digestor").(string) == "client" {
+			if err := data.Set("password", passwordStateDigest(userPassword)); err != nil {
+				return diag.Errorf("error setting password : %s ", err)
+			}
+		}
+	}
 	str := database + "." + userName
 	encoded := base64.StdEncoding.EncodeToString([]byte(str))
 	data.SetId(encoded)
 	return resourceDatabaseUserRead(ctx, data, i)
 }
 
-func resourceDatabaseUserParseId(id string) (string, string, error) {
-	result, errEncoding := base64.StdEncoding.DecodeString(id)
+// resourceDatabaseUserImport accepts a base64(db.user) state ID, a plain
+// "database.username" string, or a raw X.509 subject DN imported as
+// "$external.<subject>".
+func resourceDatabaseUserImport(ctx context.Context, data *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+	username, database, err := resourceDatabaseUserParseId(data.Id())
+	if err != nil {
+		return nil, err
+	}
+	if err := data.Set("auth_database", database); err != nil {
+		return nil, err
+	}
+	if database == "$external" {
+		// Every $external ID is imported as an X.509 user: its identity half goes into
+		// x509_subject and "name" is left empty. LDAP (PLAIN) and Kerberos (GSSAPI)
+		// users on $external are not told apart here, so their name lands in
+		// x509_subject as well.
+		if err := data.Set("x509_subject", username); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := data.Set("name", username); err != nil {
+			return nil, err
+		}
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(database + "." + username))
+	data.SetId(encoded)
+	return []*schema.ResourceData{data}, nil
+}
 
-	if errEncoding != nil {
-		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+// resourceDatabaseUserParseId accepts either the internal base64(db.user) state ID,
+// or a plain "database.username" string so `terraform import mongodb_db_user.foo
+// admin.alice` works without the user having to base64-encode anything themselves.
+func resourceDatabaseUserParseId(id string) (string, string, error) {
+	raw := id
+	if decoded, errEncoding := base64.StdEncoding.DecodeString(id); errEncoding == nil {
+		raw = string(decoded)
 	}
-	parts := strings.SplitN(string(result), ".", 2)
+
+	parts := strings.SplitN(raw, ".", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 		return "", "", fmt.Errorf("unexpected format of ID (%s), expected attribute1.attribute2", id)
 	}
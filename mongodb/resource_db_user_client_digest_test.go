@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccDatabaseUser_clientDigestMatchesServerCredentials asserts that with
+// password_digestor = "client", the SCRAM-SHA-1 storedKey/serverKey the server ends up
+// with (computed by the server from mongoPasswordDigest as pwd + its own salt) matches
+// what computeScramCredentials derives locally from that same digest and salt.
+func TestAccDatabaseUser_clientDigestMatchesServerCredentials(t *testing.T) {
+	const username = "tf_acc_client_digest"
+	const password = "s3cr3t-password"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatabaseUserClientDigestConfig(username, password),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckClientDigestMatchesServer("admin", username, password),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatabaseUserClientDigestConfig(username string, password string) string {
+	return fmt.Sprintf(`
+resource "mongodb_db_user" "client_digest" {
+  auth_database     = "admin"
+  name              = %q
+  password          = %q
+  password_digestor = "client"
+
+  role {
+    db   = "admin"
+    role = "read"
+  }
+}
+`, username, password)
+}
+
+func testAccCheckClientDigestMatchesServer(database string, username string, password string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		meta := testAccProvider.Meta().(*MongoProviderMeta)
+		result, err := getUserCredentials(meta.Client, username, database)
+		if err != nil {
+			return err
+		}
+		if len(result.Users) == 0 {
+			return fmt.Errorf("expected usersInfo to return %s", username)
+		}
+
+		creds, ok := result.Users[0].Credentials[mechanismSCRAMSHA1]
+		if !ok {
+			return fmt.Errorf("expected %s to have %s credentials", username, mechanismSCRAMSHA1)
+		}
+
+		salt, err := base64.StdEncoding.DecodeString(creds.Salt)
+		if err != nil {
+			return fmt.Errorf("could not decode server salt: %s", err)
+		}
+
+		digest := mongoPasswordDigest(username, password)
+		local, err := computeScramCredentials(mechanismSCRAMSHA1, digest, salt, creds.IterationCount)
+		if err != nil {
+			return err
+		}
+		_, storedKey, serverKey := local.encode()
+
+		if storedKey != creds.StoredKey {
+			return fmt.Errorf("locally-computed storedKey %s does not match server's %s", storedKey, creds.StoredKey)
+		}
+		if serverKey != creds.ServerKey {
+			return fmt.Errorf("locally-computed serverKey %s does not match server's %s", serverKey, creds.ServerKey)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccDatabaseUser_rolesOnlyUpdatePreservesCredentials asserts that updating only
+// a user's roles goes through grantRolesToUser/revokeRolesFromUser rather than
+// dropUser+createUser, so the user's SCRAM credentials are untouched by the update.
+func TestAccDatabaseUser_rolesOnlyUpdatePreservesCredentials(t *testing.T) {
+	var before, after userCredentialsInfoResult
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatabaseUserRolesConfig([]string{"read"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCaptureUserCredentials("admin", "tf_acc_roles_only", &before),
+				),
+			},
+			{
+				Config: testAccDatabaseUserRolesConfig([]string{"read", "readWrite"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCaptureUserCredentials("admin", "tf_acc_roles_only", &after),
+					testAccCheckCredentialsUnchanged(&before, &after),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatabaseUserRolesConfig(roles []string) string {
+	roleBlocks := ""
+	for _, r := range roles {
+		roleBlocks += fmt.Sprintf(`
+  role {
+    db   = "admin"
+    role = %q
+  }
+`, r)
+	}
+	return fmt.Sprintf(`
+resource "mongodb_db_user" "roles_only" {
+  auth_database = "admin"
+  name          = "tf_acc_roles_only"
+  password      = "s3cr3t-password"
+%s
+}
+`, roleBlocks)
+}
+
+// testAccCaptureUserCredentials reads the user's SCRAM credentials straight from the
+// server via the provider's own client, so the capture compares the server's own
+// storedKey/serverKey rather than anything recorded in Terraform state.
+func testAccCaptureUserCredentials(database string, username string, out *userCredentialsInfoResult) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		meta := testAccProvider.Meta().(*MongoProviderMeta)
+		result, err := getUserCredentials(meta.Client, username, database)
+		if err != nil {
+			return err
+		}
+		*out = result
+		return nil
+	}
+}
+
+func testAccCheckCredentialsUnchanged(before *userCredentialsInfoResult, after *userCredentialsInfoResult) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if len(before.Users) == 0 || len(after.Users) == 0 {
+			return fmt.Errorf("expected credentials to be captured before and after the update")
+		}
+		for mechanism, beforeCreds := range before.Users[0].Credentials {
+			afterCreds, ok := after.Users[0].Credentials[mechanism]
+			if !ok {
+				return fmt.Errorf("mechanism %s missing after update", mechanism)
+			}
+			if beforeCreds.StoredKey != afterCreds.StoredKey || beforeCreds.ServerKey != afterCreds.ServerKey {
+				return fmt.Errorf("credentials for mechanism %s changed across a roles-only update", mechanism)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccDatabaseUser_x509RolesUpdate asserts that updating roles on an X.509 user
+// works: Update must look the user up by x509_subject (what was actually stored in
+// createUser), not by the decorative "name" attribute.
+func TestAccDatabaseUser_x509RolesUpdate(t *testing.T) {
+	const subject = "CN=tf-acc-x509,OU=Ops,O=Acme"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatabaseUserX509Config(subject, []string{"read"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb_db_user.x509", "role.#", "1"),
+				),
+			},
+			{
+				Config: testAccDatabaseUserX509Config(subject, []string{"read", "readWrite"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb_db_user.x509", "role.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatabaseUserX509Config(subject string, roles []string) string {
+	roleBlocks := ""
+	for _, r := range roles {
+		roleBlocks += fmt.Sprintf(`
+  role {
+    db   = "admin"
+    role = %q
+  }
+`, r)
+	}
+	return fmt.Sprintf(`
+resource "mongodb_db_user" "x509" {
+  auth_database = "$external"
+  name          = "tf-acc-x509-label"
+  x509_subject  = %q
+%s
+}
+`, subject, roleBlocks)
+}
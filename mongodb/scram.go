@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// scramCredentials is the set of SCRAM values derived from a password for a
+// single mechanism, per RFC 5802: StoredKey/ServerKey are what the server
+// would otherwise compute itself from pwd+salt+iterations.
+type scramCredentials struct {
+	Salt       []byte
+	Iterations int
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// mongoPasswordDigest returns the legacy MD5 digest MongoDB's createUser/updateUser
+// commands accept as `pwd` when `digestPassword: false` is set, so the raw password
+// itself never has to be sent on the wire. This is the only form of client-side
+// pre-hashing the createUser/updateUser commands actually accept; MongoDB always
+// derives the SCRAM StoredKey/ServerKey itself from this digest and a
+// server-generated salt, it does not accept them directly.
+func mongoPasswordDigest(username string, password string) string {
+	sum := md5.Sum([]byte(username + ":mongo:" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// computeScramCredentials derives the StoredKey/ServerKey a SCRAM-SHA-1 or
+// SCRAM-SHA-256 server would end up with for the given password, salt and
+// iteration count.
+func computeScramCredentials(mechanism string, password string, salt []byte, iterations int) (scramCredentials, error) {
+	var newHash func() hash.Hash
+	switch mechanism {
+	case "SCRAM-SHA-1":
+		newHash = sha1.New
+	case "SCRAM-SHA-256":
+		newHash = sha256.New
+	default:
+		return scramCredentials{}, fmt.Errorf("unsupported SCRAM mechanism %q", mechanism)
+	}
+
+	saltedPassword := pbkdf2Key(newHash, []byte(password), salt, iterations, newHash().Size())
+	clientKey := hmacSum(newHash, saltedPassword, []byte("Client Key"))
+	storedKey := hashSum(newHash, clientKey)
+	serverKey := hmacSum(newHash, saltedPassword, []byte("Server Key"))
+
+	return scramCredentials{
+		Salt:       salt,
+		Iterations: iterations,
+		StoredKey:  storedKey,
+		ServerKey:  serverKey,
+	}, nil
+}
+
+func (c scramCredentials) encode() (salt string, storedKey string, serverKey string) {
+	return base64.StdEncoding.EncodeToString(c.Salt),
+		base64.StdEncoding.EncodeToString(c.StoredKey),
+		base64.StdEncoding.EncodeToString(c.ServerKey)
+}
+
+func hmacSum(newHash func() hash.Hash, key []byte, data []byte) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hashSum(newHash func() hash.Hash, data []byte) []byte {
+	h := newHash()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// pbkdf2Key implements RFC 2898 PBKDF2 using the given HMAC hash, avoiding a
+// dependency on golang.org/x/crypto/pbkdf2 for this one call site.
+func pbkdf2Key(newHash func() hash.Hash, password []byte, salt []byte, iterations int, keyLen int) []byte {
+	prf := hmac.New(newHash, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var result []byte
+	buf := make([]byte, 4)
+	for block := 1; block <= numBlocks; block++ {
+		buf[0] = byte(block >> 24)
+		buf[1] = byte(block >> 16)
+		buf[2] = byte(block >> 8)
+		buf[3] = byte(block)
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(buf)
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(nil)
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		result = append(result, t...)
+	}
+
+	return result[:keyLen]
+}
@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+)
+
+// TestPbkdf2KeySHA1KnownVector checks pbkdf2Key against the RFC 6070 PBKDF2-HMAC-SHA1
+// test vector (password="password", salt="salt", c=1, dkLen=20), independent of any
+// SCRAM-specific logic.
+func TestPbkdf2KeySHA1KnownVector(t *testing.T) {
+	got := pbkdf2Key(sha1.New, []byte("password"), []byte("salt"), 1, 20)
+	want := "0c60c80f961f0e71f3a9b524af6012062fe037a6"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("pbkdf2Key = %x, want %s", got, want)
+	}
+}
+
+func TestMongoPasswordDigestIsDeterministic(t *testing.T) {
+	a := mongoPasswordDigest("alice", "s3cr3t")
+	b := mongoPasswordDigest("alice", "s3cr3t")
+	if a != b {
+		t.Errorf("mongoPasswordDigest is not deterministic: %s != %s", a, b)
+	}
+	if a == mongoPasswordDigest("alice", "other") {
+		t.Errorf("mongoPasswordDigest did not change with password")
+	}
+	if a == mongoPasswordDigest("bob", "s3cr3t") {
+		t.Errorf("mongoPasswordDigest did not change with username")
+	}
+}
+
+func TestComputeScramCredentials(t *testing.T) {
+	salt := []byte("fixed-test-salt-")
+
+	t.Run("rejects unsupported mechanism", func(t *testing.T) {
+		if _, err := computeScramCredentials("SCRAM-SHA-512", "password", salt, 4096); err == nil {
+			t.Fatal("expected an error for an unsupported mechanism")
+		}
+	})
+
+	for _, mechanism := range []string{"SCRAM-SHA-1", "SCRAM-SHA-256"} {
+		t.Run(mechanism, func(t *testing.T) {
+			a, err := computeScramCredentials(mechanism, "password", salt, 4096)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			b, err := computeScramCredentials(mechanism, "password", salt, 4096)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if hex.EncodeToString(a.StoredKey) != hex.EncodeToString(b.StoredKey) ||
+				hex.EncodeToString(a.ServerKey) != hex.EncodeToString(b.ServerKey) {
+				t.Errorf("%s credentials are not deterministic for identical inputs", mechanism)
+			}
+
+			other, err := computeScramCredentials(mechanism, "different-password", salt, 4096)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if hex.EncodeToString(a.StoredKey) == hex.EncodeToString(other.StoredKey) {
+				t.Errorf("%s StoredKey did not change with password", mechanism)
+			}
+		})
+	}
+}
+
+func TestCredentialDigestChangesWithPassword(t *testing.T) {
+	a, err := credentialDigest("password-one")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := credentialDigest("password-two")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a == b {
+		t.Errorf("credentialDigest did not change with password")
+	}
+}